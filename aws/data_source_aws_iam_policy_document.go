@@ -0,0 +1,229 @@
+package aws
+
+import (
+	"encoding/json"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceAwsIamPolicyDocument() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceAwsIamPolicyDocumentRead,
+
+		Schema: map[string]*schema.Schema{
+			"policy_id": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"source_json": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"override_json": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"statement": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"sid": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"effect": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "Allow",
+						},
+						"actions": &schema.Schema{
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Set:      schema.HashString,
+						},
+						"not_actions": &schema.Schema{
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Set:      schema.HashString,
+						},
+						"resources": &schema.Schema{
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Set:      schema.HashString,
+						},
+						"not_resources": &schema.Schema{
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+							Set:      schema.HashString,
+						},
+						"principals": dataSourceAwsIamPolicyPrincipalSchema(),
+
+						"not_principals": dataSourceAwsIamPolicyPrincipalSchema(),
+
+						"condition": &schema.Schema{
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"test": &schema.Schema{
+										Type:     schema.TypeString,
+										Required: true,
+									},
+									"variable": &schema.Schema{
+										Type:     schema.TypeString,
+										Required: true,
+									},
+									"values": &schema.Schema{
+										Type:     schema.TypeList,
+										Required: true,
+										Elem:     &schema.Schema{Type: schema.TypeString},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"json": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceAwsIamPolicyPrincipalSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeSet,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": &schema.Schema{
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				"identifiers": &schema.Schema{
+					Type:     schema.TypeList,
+					Required: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceAwsIamPolicyDocumentRead(d *schema.ResourceData, meta interface{}) error {
+	doc := &IAMPolicyDoc{
+		Version: "2012-10-17",
+	}
+
+	if policyId, hasPolicyId := d.GetOk("policy_id"); hasPolicyId {
+		doc.Id = policyId.(string)
+	}
+
+	var statements []*IAMPolicyStatement
+	for _, stmtI := range d.Get("statement").([]interface{}) {
+		stmt := stmtI.(map[string]interface{})
+		statement := &IAMPolicyStatement{
+			Effect: stmt["effect"].(string),
+		}
+
+		if sid, ok := stmt["sid"]; ok {
+			statement.Sid = sid.(string)
+		}
+
+		if actions := stmt["actions"].(*schema.Set).List(); len(actions) > 0 {
+			statement.Actions = iamPolicyDecodeConfigStringList(actions)
+		}
+		if notActions := stmt["not_actions"].(*schema.Set).List(); len(notActions) > 0 {
+			statement.NotActions = iamPolicyDecodeConfigStringList(notActions)
+		}
+
+		if resources := stmt["resources"].(*schema.Set).List(); len(resources) > 0 {
+			statement.Resources = iamPolicyDecodeConfigStringList(resources)
+		}
+		if notResources := stmt["not_resources"].(*schema.Set).List(); len(notResources) > 0 {
+			statement.NotResources = iamPolicyDecodeConfigStringList(notResources)
+		}
+
+		if principals := stmt["principals"].(*schema.Set).List(); len(principals) > 0 {
+			statement.Principals = dataSourceAwsIamPolicyPrincipalSet(principals)
+		}
+		if notPrincipals := stmt["not_principals"].(*schema.Set).List(); len(notPrincipals) > 0 {
+			statement.NotPrincipals = dataSourceAwsIamPolicyPrincipalSet(notPrincipals)
+		}
+
+		if conditions := stmt["condition"].(*schema.Set).List(); len(conditions) > 0 {
+			statement.Conditions = dataSourceAwsIamPolicyConditionSet(conditions)
+		}
+
+		statements = append(statements, statement)
+	}
+	doc.Statements = statements
+
+	if sourceJSON, hasSourceJSON := d.GetOk("source_json"); hasSourceJSON {
+		sourceDoc := &IAMPolicyDoc{}
+		if err := json.Unmarshal([]byte(sourceJSON.(string)), sourceDoc); err != nil {
+			return err
+		}
+		doc = MergePolicyDocs(sourceDoc, doc)
+	}
+
+	if overrideJSON, hasOverrideJSON := d.GetOk("override_json"); hasOverrideJSON {
+		overrideDoc := &IAMPolicyDoc{}
+		if err := json.Unmarshal([]byte(overrideJSON.(string)), overrideDoc); err != nil {
+			return err
+		}
+		doc = MergePolicyDocs(doc, overrideDoc)
+	}
+
+	doc.EscapeVariables()
+	doc.EnsureVersionForVariables()
+	if err := doc.Validate(); err != nil {
+		return err
+	}
+
+	doc.DeDupSids()
+
+	jsonDoc, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		// should never happen if the above code is correct
+		return err
+	}
+
+	d.Set("json", string(jsonDoc))
+	d.SetId(resource.UniqueId())
+
+	return nil
+}
+
+func dataSourceAwsIamPolicyPrincipalSet(principals []interface{}) IAMPolicyStatementPrincipalSet {
+	var set IAMPolicyStatementPrincipalSet
+	for _, principal := range principals {
+		principalMap := principal.(map[string]interface{})
+		set = append(set, IAMPolicyStatementPrincipal{
+			Type:        principalMap["type"].(string),
+			Identifiers: iamPolicyDecodeConfigStringList(principalMap["identifiers"].([]interface{})),
+		})
+	}
+	return set
+}
+
+func dataSourceAwsIamPolicyConditionSet(conditions []interface{}) IAMPolicyStatementConditionSet {
+	var set IAMPolicyStatementConditionSet
+	for _, condition := range conditions {
+		conditionMap := condition.(map[string]interface{})
+		set = append(set, IAMPolicyStatementCondition{
+			Test:     conditionMap["test"].(string),
+			Variable: conditionMap["variable"].(string),
+			Values:   iamPolicyDecodeConfigStringList(conditionMap["values"].([]interface{})),
+		})
+	}
+	return set
+}
@@ -0,0 +1,227 @@
+package aws
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestDataSourceAwsIamPolicyDocumentRead_sourceAndOverride(t *testing.T) {
+	sourceJSON := `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Sid": "AllowRead",
+				"Effect": "Allow",
+				"Action": "s3:GetObject",
+				"Resource": "*",
+				"Principal": {"AWS": ["arn:aws:iam::111111111111:root", "arn:aws:iam::222222222222:root"]}
+			},
+			{
+				"Sid": "Shared",
+				"Effect": "Allow",
+				"Action": "s3:ListBucket",
+				"Resource": "*"
+			}
+		]
+	}`
+	overrideJSON := `{
+		"Statement": [
+			{"Sid": "Shared", "Effect": "Deny", "Action": "s3:ListBucket", "Resource": "*"}
+		]
+	}`
+
+	r := dataSourceAwsIamPolicyDocument()
+	d := r.Data(nil)
+	d.Set("source_json", sourceJSON)
+	d.Set("override_json", overrideJSON)
+
+	if err := dataSourceAwsIamPolicyDocumentRead(d, nil); err != nil {
+		t.Fatalf("unexpected error from Read: %s", err)
+	}
+
+	var doc IAMPolicyDoc
+	if err := json.Unmarshal([]byte(d.Get("json").(string)), &doc); err != nil {
+		t.Fatalf("unexpected error unmarshaling json attribute: %s", err)
+	}
+
+	if len(doc.Statements) != 2 {
+		t.Fatalf("expected 2 statements after merging source_json and override_json, got %d", len(doc.Statements))
+	}
+
+	for _, s := range doc.Statements {
+		switch s.Sid {
+		case "AllowRead":
+			if len(s.Principals) != 1 || s.Principals[0].Type != "AWS" {
+				t.Fatalf("expected a single AWS principal for Sid AllowRead, got %#v", s.Principals)
+			}
+			identifiers, ok := s.Principals[0].Identifiers.([]string)
+			if !ok || len(identifiers) != 2 {
+				t.Errorf("expected source_json's multi-value principal array to survive the Read path, got %#v", s.Principals[0].Identifiers)
+			}
+		case "Shared":
+			if s.Effect != "Deny" {
+				t.Errorf("expected override_json's statement to win for Sid Shared, got Effect=%s", s.Effect)
+			}
+		default:
+			t.Errorf("unexpected Sid %s in merged document", s.Sid)
+		}
+	}
+}
+
+func TestDataSourceAwsIamPolicyDocumentRead_overrideVariablesEscapedAndVersioned(t *testing.T) {
+	overrideJSON := `{
+		"Statement": [
+			{"Sid": "Var", "Effect": "Allow", "Action": "s3:GetObject", "Resource": "&{aws:username}"}
+		]
+	}`
+
+	r := dataSourceAwsIamPolicyDocument()
+	d := r.Data(nil)
+	d.Set("override_json", overrideJSON)
+
+	if err := dataSourceAwsIamPolicyDocumentRead(d, nil); err != nil {
+		t.Fatalf("unexpected error from Read: %s", err)
+	}
+
+	var doc IAMPolicyDoc
+	if err := json.Unmarshal([]byte(d.Get("json").(string)), &doc); err != nil {
+		t.Fatalf("unexpected error unmarshaling json attribute: %s", err)
+	}
+
+	if doc.Version != iamPolicyDocVersion2012 {
+		t.Errorf("expected override_json's policy variable to bump the final Version to %q, got %q", iamPolicyDocVersion2012, doc.Version)
+	}
+	if len(doc.Statements) != 1 || doc.Statements[0].Resources != "${aws:username}" {
+		t.Errorf("expected override_json's &{...} escape hatch to be rewritten to a literal IAM policy variable, got %#v", doc.Statements)
+	}
+}
+
+func TestDataSourceAwsIamPolicyDocumentRead_arrayValuedVariablesEscaped(t *testing.T) {
+	sourceJSON := `{
+		"Statement": [
+			{
+				"Sid": "Var",
+				"Effect": "Allow",
+				"Action": ["s3:GetObject", "s3:Get&{aws:username}Object"],
+				"Resource": ["arn:aws:s3:::&{aws:username}/*", "arn:aws:s3:::other/*"]
+			}
+		]
+	}`
+
+	r := dataSourceAwsIamPolicyDocument()
+	d := r.Data(nil)
+	d.Set("source_json", sourceJSON)
+
+	if err := dataSourceAwsIamPolicyDocumentRead(d, nil); err != nil {
+		t.Fatalf("unexpected error from Read: %s", err)
+	}
+
+	jsonDoc := d.Get("json").(string)
+	if strings.Contains(jsonDoc, "&{") {
+		t.Errorf("expected no remaining &{ escapes in array-valued Action/Resource, got %s", jsonDoc)
+	}
+	if !strings.Contains(jsonDoc, "${aws:username}") {
+		t.Errorf("expected escaped variable in array-valued Action/Resource, got %s", jsonDoc)
+	}
+
+	var doc IAMPolicyDoc
+	if err := json.Unmarshal([]byte(jsonDoc), &doc); err != nil {
+		t.Fatalf("unexpected error unmarshaling json attribute: %s", err)
+	}
+	if doc.Version != iamPolicyDocVersion2012 {
+		t.Errorf("expected an array-valued policy variable to be detected and bump Version to %q, got %q", iamPolicyDocVersion2012, doc.Version)
+	}
+}
+
+func TestDataSourceAwsIamPolicyDocumentRead_statementBlocks(t *testing.T) {
+	r := dataSourceAwsIamPolicyDocument()
+
+	principalSchema := dataSourceAwsIamPolicyPrincipalSchema().Elem.(*schema.Resource)
+	conditionSchema := r.Schema["statement"].Elem.(*schema.Resource).Schema["condition"].Elem.(*schema.Resource)
+
+	d := r.Data(nil)
+	d.Set("statement", []interface{}{
+		map[string]interface{}{
+			"sid":           "1",
+			"effect":        "Allow",
+			"actions":       schema.NewSet(schema.HashString, []interface{}{"s3:GetObject"}),
+			"not_actions":   schema.NewSet(schema.HashString, []interface{}{"s3:DeleteObject"}),
+			"resources":     schema.NewSet(schema.HashString, []interface{}{"arn:aws:s3:::my-bucket/*"}),
+			"not_resources": schema.NewSet(schema.HashString, []interface{}{"arn:aws:s3:::my-bucket/private/*"}),
+			"principals": schema.NewSet(schema.HashResource(principalSchema), []interface{}{
+				map[string]interface{}{
+					"type":        "AWS",
+					"identifiers": []interface{}{"arn:aws:iam::111111111111:root"},
+				},
+			}),
+			"not_principals": schema.NewSet(schema.HashResource(principalSchema), []interface{}{
+				map[string]interface{}{
+					"type":        "AWS",
+					"identifiers": []interface{}{"arn:aws:iam::222222222222:root"},
+				},
+			}),
+			"condition": schema.NewSet(schema.HashResource(conditionSchema), []interface{}{
+				map[string]interface{}{
+					"test":     "StringEquals",
+					"variable": "s3:prefix",
+					"values":   []interface{}{"home/"},
+				},
+			}),
+		},
+	})
+
+	if err := dataSourceAwsIamPolicyDocumentRead(d, nil); err != nil {
+		t.Fatalf("unexpected error from Read: %s", err)
+	}
+
+	var doc IAMPolicyDoc
+	if err := json.Unmarshal([]byte(d.Get("json").(string)), &doc); err != nil {
+		t.Fatalf("unexpected error unmarshaling json attribute: %s", err)
+	}
+
+	if len(doc.Statements) != 1 {
+		t.Fatalf("expected 1 statement built from the statement block, got %d", len(doc.Statements))
+	}
+	stmt := doc.Statements[0]
+
+	if stmt.Actions != "s3:GetObject" {
+		t.Errorf("expected actions to produce Action=%q, got %#v", "s3:GetObject", stmt.Actions)
+	}
+	if stmt.NotActions != "s3:DeleteObject" {
+		t.Errorf("expected not_actions to produce NotAction=%q, got %#v", "s3:DeleteObject", stmt.NotActions)
+	}
+	if stmt.Resources != "arn:aws:s3:::my-bucket/*" {
+		t.Errorf("expected resources to produce Resource=%q, got %#v", "arn:aws:s3:::my-bucket/*", stmt.Resources)
+	}
+	if stmt.NotResources != "arn:aws:s3:::my-bucket/private/*" {
+		t.Errorf("expected not_resources to produce NotResource=%q, got %#v", "arn:aws:s3:::my-bucket/private/*", stmt.NotResources)
+	}
+
+	if len(stmt.Principals) != 1 || stmt.Principals[0].Type != "AWS" {
+		t.Fatalf("expected a single AWS principal, got %#v", stmt.Principals)
+	}
+	if ids, ok := stmt.Principals[0].Identifiers.([]string); !ok || len(ids) != 1 || ids[0] != "arn:aws:iam::111111111111:root" {
+		t.Errorf("unexpected principals identifiers: %#v", stmt.Principals[0].Identifiers)
+	}
+
+	if len(stmt.NotPrincipals) != 1 || stmt.NotPrincipals[0].Type != "AWS" {
+		t.Fatalf("expected a single AWS not_principal, got %#v", stmt.NotPrincipals)
+	}
+	if ids, ok := stmt.NotPrincipals[0].Identifiers.([]string); !ok || len(ids) != 1 || ids[0] != "arn:aws:iam::222222222222:root" {
+		t.Errorf("unexpected not_principals identifiers: %#v", stmt.NotPrincipals[0].Identifiers)
+	}
+
+	if len(stmt.Conditions) != 1 {
+		t.Fatalf("expected a single condition, got %#v", stmt.Conditions)
+	}
+	cond := stmt.Conditions[0]
+	if cond.Test != "StringEquals" || cond.Variable != "s3:prefix" {
+		t.Errorf("unexpected condition test/variable: %#v", cond)
+	}
+	if values, ok := cond.Values.([]string); !ok || len(values) != 1 || values[0] != "home/" {
+		t.Errorf("unexpected condition values: %#v", cond.Values)
+	}
+}
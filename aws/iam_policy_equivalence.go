@@ -0,0 +1,194 @@
+package aws
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// PoliciesAreEquivalent tests that two policy documents are semantically
+// equivalent JSON as far as IAM is concerned, ignoring differences that AWS
+// itself normalizes away: statement order (so long as every statement has a
+// Sid), ordering within Action/Resource/Principal/Condition values, and
+// single-element slices vs. bare strings.
+func PoliciesAreEquivalent(a, b string) (bool, error) {
+	if a == b {
+		return true, nil
+	}
+
+	var docA, docB IAMPolicyDoc
+	if err := json.Unmarshal([]byte(a), &docA); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal([]byte(b), &docB); err != nil {
+		return false, err
+	}
+
+	return policyDocsAreEquivalent(&docA, &docB), nil
+}
+
+func policyDocsAreEquivalent(a, b *IAMPolicyDoc) bool {
+	if a.Version != b.Version {
+		return false
+	}
+	if a.Id != b.Id {
+		return false
+	}
+	if len(a.Statements) != len(b.Statements) {
+		return false
+	}
+
+	aStatements := a.Statements
+	bStatements := b.Statements
+	if allStatementsHaveSids(aStatements) && allStatementsHaveSids(bStatements) {
+		aStatements = sortStatementsBySid(aStatements)
+		bStatements = sortStatementsBySid(bStatements)
+	}
+
+	for i := range aStatements {
+		if !statementsAreEquivalent(aStatements[i], bStatements[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func allStatementsHaveSids(statements []*IAMPolicyStatement) bool {
+	for _, s := range statements {
+		if s.Sid == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func sortStatementsBySid(statements []*IAMPolicyStatement) []*IAMPolicyStatement {
+	sorted := make([]*IAMPolicyStatement, len(statements))
+	copy(sorted, statements)
+	for i := 1; i < len(sorted); i++ {
+		for j := i; j > 0 && sorted[j-1].Sid > sorted[j].Sid; j-- {
+			sorted[j-1], sorted[j] = sorted[j], sorted[j-1]
+		}
+	}
+	return sorted
+}
+
+func statementsAreEquivalent(a, b *IAMPolicyStatement) bool {
+	if a.Sid != b.Sid {
+		return false
+	}
+	if a.Effect != b.Effect {
+		return false
+	}
+	if !policyValuesAreEquivalent(a.Actions, b.Actions) {
+		return false
+	}
+	if !policyValuesAreEquivalent(a.NotActions, b.NotActions) {
+		return false
+	}
+	if !policyValuesAreEquivalent(a.Resources, b.Resources) {
+		return false
+	}
+	if !policyValuesAreEquivalent(a.NotResources, b.NotResources) {
+		return false
+	}
+	if !principalSetsAreEquivalent(a.Principals, b.Principals) {
+		return false
+	}
+	if !principalSetsAreEquivalent(a.NotPrincipals, b.NotPrincipals) {
+		return false
+	}
+	if !conditionSetsAreEquivalent(a.Conditions, b.Conditions) {
+		return false
+	}
+	return true
+}
+
+// policyValuesAreEquivalent compares two Action/Resource-shaped values,
+// treating a single-element string slice the same as a bare string and
+// ignoring ordering.
+func policyValuesAreEquivalent(a, b interface{}) bool {
+	return reflect.DeepEqual(normalizePolicyValue(a), normalizePolicyValue(b))
+}
+
+func normalizePolicyValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return t
+	case []string:
+		return sortAndCollapseStrings(t)
+	case []interface{}:
+		strs := make([]string, len(t))
+		for i, s := range t {
+			strs[i] = s.(string)
+		}
+		return sortAndCollapseStrings(strs)
+	default:
+		return v
+	}
+}
+
+func sortAndCollapseStrings(strs []string) interface{} {
+	sorted := append([]string{}, strs...)
+	for i := 1; i < len(sorted); i++ {
+		for j := i; j > 0 && sorted[j-1] > sorted[j]; j-- {
+			sorted[j-1], sorted[j] = sorted[j], sorted[j-1]
+		}
+	}
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	return sorted
+}
+
+func principalSetsAreEquivalent(a, b IAMPolicyStatementPrincipalSet) bool {
+	return reflect.DeepEqual(normalizePrincipalSet(a), normalizePrincipalSet(b))
+}
+
+// normalizePrincipalSet maps each principal set to a Type -> sorted
+// identifiers map, also folding the bare "*" shorthand into "AWS": "*" the
+// way IAM does.
+func normalizePrincipalSet(ps IAMPolicyStatementPrincipalSet) map[string]interface{} {
+	out := map[string]interface{}{}
+	for _, p := range ps {
+		typ := p.Type
+		if typ == "*" {
+			typ = "AWS"
+		}
+		out[typ] = normalizePolicyValue(p.Identifiers)
+	}
+	return out
+}
+
+func conditionSetsAreEquivalent(a, b IAMPolicyStatementConditionSet) bool {
+	return reflect.DeepEqual(normalizeConditionSet(a), normalizeConditionSet(b))
+}
+
+// normalizeConditionSet maps each condition set to a Test -> Variable ->
+// sorted values map so that ordering differences don't produce a diff.
+func normalizeConditionSet(cs IAMPolicyStatementConditionSet) map[string]map[string]interface{} {
+	out := map[string]map[string]interface{}{}
+	for _, c := range cs {
+		if _, ok := out[c.Test]; !ok {
+			out[c.Test] = map[string]interface{}{}
+		}
+		out[c.Test][c.Variable] = normalizePolicyValue(c.Values)
+	}
+	return out
+}
+
+// suppressEquivalentAwsPolicyDiffs is a schema.DiffSuppressFunc for IAM-style
+// policy attributes (policy, access_policies, assume_role_policy, ...) that
+// suppresses the diff whenever AWS has returned a structurally identical
+// policy with reordered principals or collapsed single-item arrays.
+func suppressEquivalentAwsPolicyDiffs(k, old, new string, d *schema.ResourceData) bool {
+	equivalent, err := PoliciesAreEquivalent(old, new)
+	if err != nil {
+		return false
+	}
+	return equivalent
+}
@@ -0,0 +1,109 @@
+package aws
+
+import (
+	"testing"
+)
+
+func TestPoliciesAreEquivalent(t *testing.T) {
+	cases := []struct {
+		name       string
+		policy1    string
+		policy2    string
+		equivalent bool
+	}{
+		{
+			name:       "identical policies",
+			policy1:    `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Action":"ec2:Describe*","Resource":"*"}]}`,
+			policy2:    `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Action":"ec2:Describe*","Resource":"*"}]}`,
+			equivalent: true,
+		},
+		{
+			name:       "reordered principals",
+			policy1:    `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Principal":{"AWS":["111111111111","222222222222"]},"Action":"*","Resource":"*"}]}`,
+			policy2:    `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Principal":{"AWS":["222222222222","111111111111"]},"Action":"*","Resource":"*"}]}`,
+			equivalent: true,
+		},
+		{
+			name:       "single-item array collapsed to string",
+			policy1:    `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Action":["ec2:DescribeInstances"],"Resource":"*"}]}`,
+			policy2:    `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Action":"ec2:DescribeInstances","Resource":"*"}]}`,
+			equivalent: true,
+		},
+		{
+			name:       "reordered statements with Sids",
+			policy1:    `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Action":"a","Resource":"*"},{"Sid":"2","Effect":"Allow","Action":"b","Resource":"*"}]}`,
+			policy2:    `{"Version":"2012-10-17","Statement":[{"Sid":"2","Effect":"Allow","Action":"b","Resource":"*"},{"Sid":"1","Effect":"Allow","Action":"a","Resource":"*"}]}`,
+			equivalent: true,
+		},
+		{
+			name:       "bare * principal normalized to AWS *",
+			policy1:    `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Principal":"*","Action":"*","Resource":"*"}]}`,
+			policy2:    `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Principal":{"AWS":"*"},"Action":"*","Resource":"*"}]}`,
+			equivalent: true,
+		},
+		{
+			name:       "three reordered identifiers still equivalent",
+			policy1:    `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Principal":{"AWS":["a","b","c"]},"Action":"*","Resource":"*"}]}`,
+			policy2:    `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Principal":{"AWS":["c","a","b"]},"Action":"*","Resource":"*"}]}`,
+			equivalent: true,
+		},
+		{
+			name:    "whitespace-only difference forces the JSON-parsed comparison path",
+			policy1: `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Action":"a","Resource":"*"}]}`,
+			policy2: `{
+				"Version": "2012-10-17",
+				"Statement": [
+					{"Sid": "1", "Effect": "Allow", "Action": "a", "Resource": "*"}
+				]
+			}`,
+			equivalent: true,
+		},
+		{
+			name:       "key case difference is still equivalent",
+			policy1:    `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Action":"a","Resource":"*"}]}`,
+			policy2:    `{"version":"2012-10-17","statement":[{"sid":"1","effect":"Allow","action":"a","resource":"*"}]}`,
+			equivalent: true,
+		},
+		{
+			name:       "different effect is not equivalent",
+			policy1:    `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Action":"a","Resource":"*"}]}`,
+			policy2:    `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Deny","Action":"a","Resource":"*"}]}`,
+			equivalent: false,
+		},
+		{
+			name:       "different number of statements is not equivalent",
+			policy1:    `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Action":"a","Resource":"*"}]}`,
+			policy2:    `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Action":"a","Resource":"*"},{"Sid":"2","Effect":"Allow","Action":"b","Resource":"*"}]}`,
+			equivalent: false,
+		},
+	}
+
+	for _, tc := range cases {
+		equivalent, err := PoliciesAreEquivalent(tc.policy1, tc.policy2)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tc.name, err)
+		}
+		if equivalent != tc.equivalent {
+			t.Errorf("%s: got equivalent=%t, want %t", tc.name, equivalent, tc.equivalent)
+		}
+	}
+}
+
+func TestPoliciesAreEquivalent_invalidJSON(t *testing.T) {
+	if _, err := PoliciesAreEquivalent("not json", `{}`); err == nil {
+		t.Fatal("expected error for invalid JSON, got nil")
+	}
+}
+
+func TestSuppressEquivalentAwsPolicyDiffs(t *testing.T) {
+	old := `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Principal":{"AWS":["111111111111","222222222222"]},"Action":"*","Resource":"*"}]}`
+	new := `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Principal":{"AWS":["222222222222","111111111111"]},"Action":"*","Resource":"*"}]}`
+
+	if !suppressEquivalentAwsPolicyDiffs("policy", old, new, nil) {
+		t.Fatal("expected diff to be suppressed for equivalent policies")
+	}
+
+	if suppressEquivalentAwsPolicyDiffs("policy", old, "not json", nil) {
+		t.Fatal("expected diff not to be suppressed when new value is invalid JSON")
+	}
+}
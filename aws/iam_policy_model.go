@@ -4,8 +4,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 )
 
+const (
+	iamPolicyDocVersion2008 = "2008-10-17"
+	iamPolicyDocVersion2012 = "2012-10-17"
+)
+
+// iamPolicyVariableReplacer turns the `&{...}` escape hatch into a literal
+// `${...}` IAM policy variable (e.g. `${aws:username}`) after Terraform has
+// finished interpolating the rest of the config, so the two don't collide at
+// plan time.
+var iamPolicyVariableReplacer = strings.NewReplacer("&{", "${")
+
 type IAMPolicyDoc struct {
 	Version    string                `json:",omitempty"`
 	Id         string                `json:",omitempty"`
@@ -54,6 +66,145 @@ func (self *IAMPolicyDoc) DeDupSids() {
 	}
 }
 
+// CollapseSingleValueArrays collapses a single-element Action/NotAction/
+// Resource/NotResource array down to a bare string, the way IAM itself
+// normalizes these fields when it echoes a policy back on read.
+func (self *IAMPolicyDoc) CollapseSingleValueArrays() {
+	for _, s := range self.Statements {
+		s.Actions = iamPolicyCollapseSingleValueArray(s.Actions)
+		s.NotActions = iamPolicyCollapseSingleValueArray(s.NotActions)
+		s.Resources = iamPolicyCollapseSingleValueArray(s.Resources)
+		s.NotResources = iamPolicyCollapseSingleValueArray(s.NotResources)
+	}
+}
+
+func iamPolicyCollapseSingleValueArray(v interface{}) interface{} {
+	switch t := v.(type) {
+	case []string:
+		if len(t) == 1 {
+			return t[0]
+		}
+	case []interface{}:
+		if len(t) == 1 {
+			return t[0]
+		}
+	}
+	return v
+}
+
+// EscapeVariables rewrites the `&{...}` escape hatch into `${...}` across
+// every Action/Resource/Principal/Condition value in the document, so
+// callers can build a statement from Terraform config (where `${...}` would
+// be treated as an interpolation) and still emit the literal IAM policy
+// variables AWS expects.
+func (self *IAMPolicyDoc) EscapeVariables() {
+	for _, s := range self.Statements {
+		s.Actions = iamPolicyEscapeVariablesValue(s.Actions)
+		s.NotActions = iamPolicyEscapeVariablesValue(s.NotActions)
+		s.Resources = iamPolicyEscapeVariablesValue(s.Resources)
+		s.NotResources = iamPolicyEscapeVariablesValue(s.NotResources)
+
+		for i, p := range s.Principals {
+			s.Principals[i].Identifiers = iamPolicyEscapeVariablesValue(p.Identifiers)
+		}
+		for i, p := range s.NotPrincipals {
+			s.NotPrincipals[i].Identifiers = iamPolicyEscapeVariablesValue(p.Identifiers)
+		}
+		for i, c := range s.Conditions {
+			s.Conditions[i].Values = iamPolicyEscapeVariablesValue(c.Values)
+		}
+	}
+}
+
+func iamPolicyEscapeVariablesValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case string:
+		return iamPolicyVariableReplacer.Replace(t)
+	case []string:
+		out := make([]string, len(t))
+		for i, s := range t {
+			out[i] = iamPolicyVariableReplacer.Replace(s)
+		}
+		return out
+	case []interface{}:
+		out := make([]string, len(t))
+		for i, s := range t {
+			out[i] = iamPolicyVariableReplacer.Replace(s.(string))
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// HasVariables reports whether any statement in the document contains a
+// literal IAM policy variable such as `${aws:username}` or `${saml:sub}`.
+// Documents using the older 2008-10-17 version reject these.
+func (self *IAMPolicyDoc) HasVariables() bool {
+	for _, s := range self.Statements {
+		if iamPolicyValueHasVariable(s.Actions) || iamPolicyValueHasVariable(s.NotActions) ||
+			iamPolicyValueHasVariable(s.Resources) || iamPolicyValueHasVariable(s.NotResources) {
+			return true
+		}
+		for _, p := range s.Principals {
+			if iamPolicyValueHasVariable(p.Identifiers) {
+				return true
+			}
+		}
+		for _, p := range s.NotPrincipals {
+			if iamPolicyValueHasVariable(p.Identifiers) {
+				return true
+			}
+		}
+		for _, c := range s.Conditions {
+			if iamPolicyValueHasVariable(c.Values) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func iamPolicyValueHasVariable(v interface{}) bool {
+	switch t := v.(type) {
+	case string:
+		return strings.Contains(t, "${")
+	case []string:
+		for _, s := range t {
+			if strings.Contains(s, "${") {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, s := range t {
+			if strings.Contains(s.(string), "${") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// EnsureVersionForVariables bumps the document's Version to 2012-10-17
+// whenever it contains a policy variable, since the older 2008-10-17
+// version rejects them.
+func (self *IAMPolicyDoc) EnsureVersionForVariables() {
+	if self.HasVariables() && self.Version != iamPolicyDocVersion2012 {
+		self.Version = iamPolicyDocVersion2012
+	}
+}
+
+// Validate returns an error if the document contains an IAM policy variable
+// but is set to a Version that doesn't support them.
+func (self *IAMPolicyDoc) Validate() error {
+	if self.HasVariables() && self.Version == iamPolicyDocVersion2008 {
+		return fmt.Errorf(
+			"IAM policy variables require a Version of %q, got %q",
+			iamPolicyDocVersion2012, self.Version)
+	}
+	return nil
+}
+
 func (ps IAMPolicyStatementPrincipalSet) MarshalJSON() ([]byte, error) {
 	raw := map[string]interface{}{}
 
@@ -105,7 +256,18 @@ func (ps *IAMPolicyStatementPrincipalSet) UnmarshalJSON(b []byte) error {
 		out = append(out, IAMPolicyStatementPrincipal{Type: "*", Identifiers: []string{"*"}})
 	case map[string]interface{}:
 		for key, value := range data.(map[string]interface{}) {
-			out = append(out, IAMPolicyStatementPrincipal{Type: key, Identifiers: value})
+			switch vt := value.(type) {
+			case string:
+				out = append(out, IAMPolicyStatementPrincipal{Type: key, Identifiers: []string{vt}})
+			case []interface{}:
+				values := make([]string, len(vt))
+				for i, v := range vt {
+					values[i] = v.(string)
+				}
+				out = append(out, IAMPolicyStatementPrincipal{Type: key, Identifiers: values})
+			default:
+				return fmt.Errorf("Unsupported data type %T for IAMPolicyStatementPrincipalSet", value)
+			}
 		}
 	default:
 		return fmt.Errorf("Unsupported data type %s for IAMPolicyStatementPrincipalSet", t)
@@ -166,6 +328,32 @@ func (cs *IAMPolicyStatementConditionSet) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// MergePolicyDocs concatenates the statements of source and override into a
+// new IAMPolicyDoc and de-dupes them by Sid, so a statement in override
+// replaces a statement in source that shares its Sid. This lets a base
+// policy (e.g. one pulled from an existing AWS-managed policy's JSON) be
+// layered with additional, more specific statements. Version and Id are
+// taken from override when it sets them, and from source otherwise.
+func MergePolicyDocs(source, override *IAMPolicyDoc) *IAMPolicyDoc {
+	merged := &IAMPolicyDoc{
+		Version: source.Version,
+		Id:      source.Id,
+	}
+	if override.Version != "" {
+		merged.Version = override.Version
+	}
+	if override.Id != "" {
+		merged.Id = override.Id
+	}
+
+	merged.Statements = append(merged.Statements, source.Statements...)
+	merged.Statements = append(merged.Statements, override.Statements...)
+
+	merged.DeDupSids()
+
+	return merged
+}
+
 func iamPolicyDecodeConfigStringList(lI []interface{}) interface{} {
 	if len(lI) == 1 {
 		return lI[0].(string)
@@ -0,0 +1,143 @@
+package aws
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestIAMPolicyDocEscapeVariables(t *testing.T) {
+	doc := &IAMPolicyDoc{
+		Version: iamPolicyDocVersion2012,
+		Statements: []*IAMPolicyStatement{
+			{
+				Sid:       "1",
+				Effect:    "Allow",
+				Actions:   "s3:GetObject",
+				Resources: []string{"arn:aws:s3:::&{aws:username}/*"},
+				Conditions: IAMPolicyStatementConditionSet{
+					{Test: "StringEquals", Variable: "s3:prefix", Values: []string{"&{saml:sub}"}},
+				},
+			},
+		},
+	}
+
+	doc.EscapeVariables()
+
+	got, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.Contains(string(got), `${aws:username}`) {
+		t.Errorf("expected escaped resource variable in %s", got)
+	}
+	if !strings.Contains(string(got), `${saml:sub}`) {
+		t.Errorf("expected escaped condition variable in %s", got)
+	}
+	if strings.Contains(string(got), "&{") {
+		t.Errorf("expected no remaining &{ escapes in %s", got)
+	}
+}
+
+func TestIAMPolicyDocEnsureVersionForVariables(t *testing.T) {
+	doc := &IAMPolicyDoc{
+		Version: iamPolicyDocVersion2008,
+		Statements: []*IAMPolicyStatement{
+			{Sid: "1", Effect: "Allow", Resources: "arn:aws:s3:::${aws:username}/*"},
+		},
+	}
+
+	doc.EnsureVersionForVariables()
+
+	if doc.Version != iamPolicyDocVersion2012 {
+		t.Errorf("expected version to be bumped to %s, got %s", iamPolicyDocVersion2012, doc.Version)
+	}
+
+	plain := &IAMPolicyDoc{
+		Version:    iamPolicyDocVersion2008,
+		Statements: []*IAMPolicyStatement{{Sid: "1", Effect: "Allow", Resources: "*"}},
+	}
+	plain.EnsureVersionForVariables()
+	if plain.Version != iamPolicyDocVersion2008 {
+		t.Errorf("expected version to be left alone when no variables are present, got %s", plain.Version)
+	}
+}
+
+func TestMergePolicyDocs(t *testing.T) {
+	source := &IAMPolicyDoc{
+		Version: iamPolicyDocVersion2012,
+		Statements: []*IAMPolicyStatement{
+			{Sid: "Base", Effect: "Allow", Actions: "s3:GetObject", Resources: "*"},
+			{Sid: "Shared", Effect: "Allow", Actions: "s3:ListBucket", Resources: "*"},
+		},
+	}
+	override := &IAMPolicyDoc{
+		Statements: []*IAMPolicyStatement{
+			{Sid: "Shared", Effect: "Deny", Actions: "s3:ListBucket", Resources: "*"},
+			{Sid: "Extra", Effect: "Allow", Actions: "s3:PutObject", Resources: "*"},
+		},
+	}
+
+	merged := MergePolicyDocs(source, override)
+
+	if merged.Version != iamPolicyDocVersion2012 {
+		t.Errorf("expected merged Version to fall back to source's, got %s", merged.Version)
+	}
+
+	if len(merged.Statements) != 3 {
+		t.Fatalf("expected 3 statements after de-duping shared Sid, got %d", len(merged.Statements))
+	}
+
+	var shared *IAMPolicyStatement
+	for _, s := range merged.Statements {
+		if s.Sid == "Shared" {
+			shared = s
+		}
+	}
+	if shared == nil {
+		t.Fatal("expected a statement with Sid \"Shared\" to survive the merge")
+	}
+	if shared.Effect != "Deny" {
+		t.Errorf("expected override's statement to win for a shared Sid, got Effect=%s", shared.Effect)
+	}
+}
+
+func TestMergePolicyDocs_overrideVersionAndId(t *testing.T) {
+	source := &IAMPolicyDoc{
+		Version:    iamPolicyDocVersion2008,
+		Statements: []*IAMPolicyStatement{{Sid: "1", Effect: "Allow", Actions: "*", Resources: "*"}},
+	}
+	override := &IAMPolicyDoc{
+		Id:         "my-policy",
+		Version:    iamPolicyDocVersion2012,
+		Statements: []*IAMPolicyStatement{{Sid: "2", Effect: "Allow", Actions: "*", Resources: "*"}},
+	}
+
+	merged := MergePolicyDocs(source, override)
+
+	if merged.Version != iamPolicyDocVersion2012 {
+		t.Errorf("expected override's Version to win when set, got %s", merged.Version)
+	}
+	if merged.Id != "my-policy" {
+		t.Errorf("expected override's Id to win when source's is blank, got %s", merged.Id)
+	}
+}
+
+func TestIAMPolicyDocValidate(t *testing.T) {
+	doc := &IAMPolicyDoc{
+		Version: iamPolicyDocVersion2008,
+		Statements: []*IAMPolicyStatement{
+			{Sid: "1", Effect: "Allow", Resources: "arn:aws:s3:::${aws:username}/*"},
+		},
+	}
+
+	if err := doc.Validate(); err == nil {
+		t.Fatal("expected an error for variables under an incompatible version")
+	}
+
+	doc.Version = iamPolicyDocVersion2012
+	if err := doc.Validate(); err != nil {
+		t.Fatalf("unexpected error once version is compatible: %s", err)
+	}
+}
@@ -0,0 +1,43 @@
+package aws
+
+import (
+	"encoding/json"
+)
+
+// normalizePolicyDocument is a schema.StateFunc for policy-shaped JSON
+// attributes (policy, access_policies, assume_role_policy, and similar
+// attributes on S3, ElasticSearch, IAM, SNS, SQS, and KMS resources). It
+// round-trips the document through IAMPolicyDoc so that principals are
+// emitted as an object keyed by type, single-element Action/NotAction/
+// Resource/NotResource arrays are collapsed to bare strings the way IAM does
+// on read, and condition/principal keys come out in a deterministic, sorted
+// order. A lone "*" (or "AWS": "*") principal is emitted as the bare string
+// "*", not as an object. Principal identifiers and condition values are
+// always emitted as arrays: single-element arrays there are not collapsed,
+// and a bare string there comes out as a one-element array. This keeps
+// Terraform from showing a diff when AWS echoes back a document that
+// differs only in this kind of formatting.
+//
+// If the value isn't valid policy JSON, it's returned unchanged so that a
+// malformed document doesn't block apply; schema validation is expected to
+// catch that case separately.
+func normalizePolicyDocument(v interface{}) string {
+	s, ok := v.(string)
+	if !ok {
+		return ""
+	}
+
+	var doc IAMPolicyDoc
+	if err := json.Unmarshal([]byte(s), &doc); err != nil {
+		return s
+	}
+
+	doc.CollapseSingleValueArrays()
+
+	normalized, err := json.Marshal(&doc)
+	if err != nil {
+		return s
+	}
+
+	return string(normalized)
+}
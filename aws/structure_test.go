@@ -0,0 +1,51 @@
+package aws
+
+import (
+	"testing"
+)
+
+func TestNormalizePolicyDocument(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "principal as bare string",
+			input:    `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Principal":"*","Action":"*","Resource":"*"}]}`,
+			expected: `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Action":"*","Resource":"*","Principal":"*"}]}`,
+		},
+		{
+			name:     "principal as object",
+			input:    `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Principal":{"AWS":["arn:aws:iam::111111111111:root"]},"Action":"*","Resource":"*"}]}`,
+			expected: `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Action":"*","Resource":"*","Principal":{"AWS":["arn:aws:iam::111111111111:root"]}}]}`,
+		},
+		{
+			name:     "single-item action array collapsed",
+			input:    `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Action":["ec2:DescribeInstances"],"Resource":"*"}]}`,
+			expected: `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Action":"ec2:DescribeInstances","Resource":"*"}]}`,
+		},
+		{
+			name: "whitespace-only difference",
+			input: `{
+				"Version": "2012-10-17",
+				"Statement": [{"Sid": "1", "Effect": "Allow", "Action": "*", "Resource": "*"}]
+			}`,
+			expected: `{"Version":"2012-10-17","Statement":[{"Sid":"1","Effect":"Allow","Action":"*","Resource":"*"}]}`,
+		},
+	}
+
+	for _, tc := range cases {
+		got := normalizePolicyDocument(tc.input)
+		if got != tc.expected {
+			t.Errorf("%s: got %s, want %s", tc.name, got, tc.expected)
+		}
+	}
+}
+
+func TestNormalizePolicyDocument_invalidJSON(t *testing.T) {
+	input := "not valid json"
+	if got := normalizePolicyDocument(input); got != input {
+		t.Errorf("expected invalid JSON to be returned unchanged, got %s", got)
+	}
+}